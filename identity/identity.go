@@ -0,0 +1,75 @@
+// Package identity resolves the git author identity that would be
+// used for a new commit, without shelling out to git.
+package identity
+
+import (
+	"os"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/pkg/errors"
+)
+
+// Author is a resolved commit identity.
+type Author struct {
+	Name      string
+	Email     string
+	Timestamp time.Time
+	Timezone  *time.Location
+}
+
+// Resolve returns the author identity that would be used for a new
+// commit in repoPath, following git's own precedence (confirmed
+// against `git var GIT_AUTHOR_IDENT`): the GIT_AUTHOR_NAME/
+// GIT_AUTHOR_EMAIL environment variables take priority over the
+// repo's local config, which in turn takes priority over the user's
+// global config.
+func Resolve(repoPath string) (Author, error) {
+	if name, email, ok := fromEnv(); ok {
+		return newAuthor(name, email), nil
+	}
+
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return Author{}, errors.Wrapf(err, "open repo %s failed", repoPath)
+	}
+
+	if name, email, ok := fromScope(repo, config.LocalScope); ok {
+		return newAuthor(name, email), nil
+	}
+
+	if name, email, ok := fromScope(repo, config.GlobalScope); ok {
+		return newAuthor(name, email), nil
+	}
+
+	return Author{}, errors.New("no author identity configured in GIT_AUTHOR_* env vars, repo config, or global config")
+}
+
+func fromScope(repo *git.Repository, scope config.Scope) (string, string, bool) {
+	cfg, err := repo.ConfigScoped(scope)
+	if err != nil {
+		return "", "", false
+	}
+
+	if cfg.User.Name == "" || cfg.User.Email == "" {
+		return "", "", false
+	}
+
+	return cfg.User.Name, cfg.User.Email, true
+}
+
+func fromEnv() (string, string, bool) {
+	name := os.Getenv("GIT_AUTHOR_NAME")
+	email := os.Getenv("GIT_AUTHOR_EMAIL")
+	if name == "" || email == "" {
+		return "", "", false
+	}
+
+	return name, email, true
+}
+
+func newAuthor(name, email string) Author {
+	now := time.Now()
+	return Author{Name: name, Email: email, Timestamp: now, Timezone: now.Location()}
+}
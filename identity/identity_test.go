@@ -0,0 +1,92 @@
+package identity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func initRepoWithConfig(t *testing.T, name, email string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("PlainInit() failed: %v", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("Config() failed: %v", err)
+	}
+	cfg.User.Name = name
+	cfg.User.Email = email
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatalf("SetConfig() failed: %v", err)
+	}
+
+	return dir
+}
+
+func TestResolvePrefersLocalConfigOverGlobal(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	global := "[user]\n\tname = Grace Hopper\n\temail = grace@example.com\n"
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(global), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	dir := initRepoWithConfig(t, "Ada Lovelace", "ada@example.com")
+
+	a, err := Resolve(dir)
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+	if a.Name != "Ada Lovelace" || a.Email != "ada@example.com" {
+		t.Errorf("Resolve() = %+v, want Ada Lovelace <ada@example.com>", a)
+	}
+}
+
+// TestResolvePrefersEnvOverConfig mirrors git's own precedence, per
+// `git var GIT_AUTHOR_IDENT`: GIT_AUTHOR_NAME/GIT_AUTHOR_EMAIL win
+// over a repo's local config, e.g. during a rebase or in CI where
+// they're commonly overridden for a single commit.
+func TestResolvePrefersEnvOverConfig(t *testing.T) {
+	dir := initRepoWithConfig(t, "Ada Lovelace", "ada@example.com")
+
+	t.Setenv("GIT_AUTHOR_NAME", "Grace Hopper")
+	t.Setenv("GIT_AUTHOR_EMAIL", "grace@example.com")
+
+	a, err := Resolve(dir)
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+	if a.Name != "Grace Hopper" || a.Email != "grace@example.com" {
+		t.Errorf("Resolve() = %+v, want the env identity to win over repo config", a)
+	}
+}
+
+func TestResolveFailsWithNoIdentityAnywhere(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatalf("PlainInit() failed: %v", err)
+	}
+
+	// Point the global config at an empty home so a real ~/.gitconfig
+	// can't supply an identity.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL"} {
+		if os.Getenv(v) != "" {
+			t.Setenv(v, "")
+		}
+	}
+
+	if _, err := Resolve(dir); err == nil {
+		t.Fatal("Resolve() succeeded with no identity configured anywhere")
+	}
+}
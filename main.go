@@ -0,0 +1,296 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// configPath is where the dev/repo registry is read from and written
+// back to, overridable for testing or multi-team setups via
+// XP_CONFIG.
+func configPath() string {
+	if p := os.Getenv("XP_CONFIG"); p != "" {
+		return p
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".xp.yml"
+	}
+
+	return filepath.Join(home, ".xp.yml")
+}
+
+func loadConfig() (*data, error) {
+	f, err := os.Open(configPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &data{}, nil
+		}
+		return nil, errors.Wrapf(err, "open config %s failed", configPath())
+	}
+	defer f.Close()
+
+	return load(f)
+}
+
+func saveConfig(d *data) error {
+	f, err := os.OpenFile(configPath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "open config %s for write failed", configPath())
+	}
+	defer f.Close()
+
+	return d.store(f)
+}
+
+// workingDir returns args[0] if given, else the process's current
+// directory, for subcommands that take an optional repo path.
+func workingDir(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "get working dir failed")
+	}
+	return wd, nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "init":
+		err = runInit(os.Args[2:])
+	case "uninstall":
+		err = runUninstall(os.Args[2:])
+	case "add-info":
+		err = runAddInfo(os.Args[2:])
+	case "verify":
+		err = runVerify(os.Args[2:])
+	case "sync":
+		err = runSync(os.Args[2:])
+	case "rotate":
+		err = runRotate(os.Args[2:])
+	case "who":
+		err = runWho(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: xp <init|uninstall|add-info|verify|sync|rotate|who> [flags] [repo-path]")
+}
+
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	overwrite := fs.Bool("overwrite", false, "adopt and chain onto an existing, non-xp hook")
+	templateDir := fs.Bool("template-dir", false, "install into git's global init.templateDir so new clones auto-enroll, instead of a specific repo")
+	devs := fs.String("devs", "", "comma-separated dev ids to register for this repo")
+	issueID := fs.String("issue-id", "", "default issue id to fall back to for this repo")
+	tracker := fs.String("tracker", "", "issue-reference style to recognize in commit messages: github, jira, or gitlab")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	xpBinPath, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "resolve xp binary path failed")
+	}
+
+	if *templateDir {
+		return initTemplateDir(xpBinPath)
+	}
+
+	wd, err := workingDir(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if err := initRepo(wd, *overwrite, xpBinPath); err != nil {
+		return err
+	}
+
+	d, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var devIDs []string
+	if *devs != "" {
+		devIDs = strings.Split(*devs, ",")
+	}
+
+	if err := d.addRepo(wd, devIDs, *issueID, *tracker); err != nil {
+		return err
+	}
+
+	return saveConfig(d)
+}
+
+// runUninstall removes only the xp-managed block from the repo's
+// hooks, leaving any foreign hook it was chained onto intact.
+func runUninstall(args []string) error {
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wd, err := workingDir(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	return uninstallRepo(wd)
+}
+
+// runAddInfo is what the installed prepare-commit-msg/commit-msg
+// hooks invoke, passing the commit message file git gave them.
+func runAddInfo(args []string) error {
+	if len(args) == 0 {
+		return errors.New("add-info requires a commit message file path")
+	}
+
+	d, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return errors.Wrap(err, "get working dir failed")
+	}
+
+	return d.appendInfo(wd, args[0])
+}
+
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	gitRange := fs.String("range", "", "commit range to verify (default @{upstream}..HEAD)")
+	signed := fs.Bool("signed", false, "also require commits to be GPG-signed by a declared dev")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	wd, err := workingDir(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	return d.verifyRange(wd, *gitRange, *signed)
+}
+
+// runSync previews (and, with -write, applies) a bulk import of devs
+// discovered in the repo's git history that aren't already declared.
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	write := fs.Bool("write", false, "write the proposed devs to the config instead of only previewing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	wd, err := workingDir(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	proposals, err := d.syncDevs(wd)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(diffSync(proposals))
+
+	if !*write {
+		return nil
+	}
+
+	d.applySync(proposals)
+
+	return saveConfig(d)
+}
+
+// runRotate advances a repo's round-robin rotation state for today,
+// a no-op if it's already been advanced today or the repo doesn't use
+// round-robin.
+func runRotate(args []string) error {
+	fs := flag.NewFlagSet("rotate", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	wd, err := workingDir(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if err := d.advanceRotation(wd, time.Now().Format("2006-01-02")); err != nil {
+		return err
+	}
+
+	return saveConfig(d)
+}
+
+// runWho previews today's rotation selection without mutating any
+// state, printing one dev id per line.
+func runWho(args []string) error {
+	fs := flag.NewFlagSet("who", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	wd, err := workingDir(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	ids, err := d.who(wd, time.Now().Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+
+	return nil
+}
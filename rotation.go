@@ -0,0 +1,171 @@
+package main
+
+import (
+	"hash/fnv"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// repoRotation configures how appendInfo (and `xp who`/`xp rotate`)
+// choose a subset of repo.Devs for a given day, instead of always
+// co-authoring the full list.
+type repoRotation struct {
+	// Strategy is one of "all" (default), "pair", "round-robin" or
+	// "weighted".
+	Strategy string `json:"strategy,omitempty"`
+	// Size is how many devs to select under "pair"/"weighted" (default
+	// 2) or "round-robin" (default 1).
+	Size int `json:"size,omitempty"`
+	// Weights scales the odds of a dev being picked under the
+	// "weighted" strategy; devs missing from Weights default to 1.
+	Weights map[string]float64 `json:"weights,omitempty"`
+
+	// LastIndex and LastDate track round-robin progress: the index
+	// into Devs last advanced to, and the date (YYYY-MM-DD) it was
+	// advanced for.
+	LastIndex int    `json:"lastIndex,omitempty"`
+	LastDate  string `json:"lastDate,omitempty"`
+}
+
+// selectRotation returns the dev ids working on repo for date
+// (YYYY-MM-DD). With no rotation configured, or strategy "all" (the
+// default), every repo dev is selected, matching the pre-rotation
+// behaviour. "pair" and "weighted" are picked deterministically from
+// date, so two commits on the same day always agree; "round-robin"
+// instead reads back whatever `xp rotate` last advanced to.
+func selectRotation(repo *repo, date string) []string {
+	rot := repo.Rotation
+	if rot == nil || rot.Strategy == "" || rot.Strategy == "all" || len(repo.Devs) == 0 {
+		return repo.Devs
+	}
+
+	switch rot.Strategy {
+	case "round-robin":
+		return rotateSlice(repo.Devs, rot.LastIndex, devOr(rot.Size, 1))
+
+	case "pair":
+		return rotateSlice(repo.Devs, dateIndex(date, len(repo.Devs)), devOr(rot.Size, 2))
+
+	case "weighted":
+		return weightedSelect(repo.Devs, rot.Weights, date, devOr(rot.Size, 2))
+
+	default:
+		return repo.Devs
+	}
+}
+
+// advanceRotation advances repo's round-robin state for date, moving
+// LastIndex past the devs it last selected so the next selectRotation
+// call returns the following dev(s). It is a no-op for strategies
+// other than "round-robin", since "pair" and "weighted" are already
+// fully determined by date, and a no-op if already advanced for date
+// so that two commits on the same day see the same pair.
+func (d *data) advanceRotation(wd, date string) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	rot := repo.Rotation
+	if rot == nil || rot.Strategy != "round-robin" || rot.LastDate == date {
+		return nil
+	}
+
+	if len(repo.Devs) == 0 {
+		return errors.Errorf("repo %s has no devs to rotate", wd)
+	}
+
+	rot.LastIndex = (rot.LastIndex + devOr(rot.Size, 1)) % len(repo.Devs)
+	rot.LastDate = date
+
+	return nil
+}
+
+// who returns the dev ids selected for repo on date, for `xp who` to
+// preview without mutating any rotation state.
+func (d *data) who(wd, date string) ([]string, error) {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return nil, errors.Errorf("no repo with path %s found", wd)
+	}
+
+	return selectRotation(repo, date), nil
+}
+
+func devOr(size, def int) int {
+	if size == 0 {
+		return def
+	}
+	return size
+}
+
+// rotateSlice returns size consecutive devs from devs, starting at
+// start and wrapping around.
+func rotateSlice(devs []string, start, size int) []string {
+	n := len(devs)
+	if size > n {
+		size = n
+	}
+	start = ((start % n) + n) % n
+
+	out := make([]string, size)
+	for i := 0; i < size; i++ {
+		out[i] = devs[(start+i)%n]
+	}
+	return out
+}
+
+// dateIndex hashes date into a stable index in [0, n), so the same
+// date always maps to the same starting point.
+func dateIndex(date string, n int) int {
+	return int(fnvHash(date) % uint32(n))
+}
+
+// weightedSelect deterministically picks size devs for date, biased
+// by weights (devs missing from weights default to 1; a dev explicitly
+// weighted 0 is excluded entirely). The same date always yields the
+// same selection.
+func weightedSelect(devs []string, weights map[string]float64, date string, size int) []string {
+	type scored struct {
+		dev   string
+		score float64
+	}
+
+	scoredDevs := make([]scored, 0, len(devs))
+	for _, dv := range devs {
+		w, ok := weights[dv]
+		if !ok {
+			w = 1
+		}
+		if w == 0 {
+			continue
+		}
+		scoredDevs = append(scoredDevs, scored{dev: dv, score: w * ticketHash(date+"\x00"+dv)})
+	}
+
+	sort.Slice(scoredDevs, func(i, j int) bool { return scoredDevs[i].score > scoredDevs[j].score })
+
+	if size > len(scoredDevs) {
+		size = len(scoredDevs)
+	}
+
+	out := make([]string, size)
+	for i := 0; i < size; i++ {
+		out[i] = scoredDevs[i].dev
+	}
+	return out
+}
+
+// ticketHash maps s to a stable pseudo-random float in [0, 1).
+func ticketHash(s string) float64 {
+	return float64(fnvHash(s)) / (float64(math.MaxUint32) + 1)
+}
+
+func fnvHash(s string) uint32 {
+	h := fnv.New32a()
+	io.WriteString(h, s)
+	return h.Sum32()
+}
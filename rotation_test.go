@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+func TestRotateSlice(t *testing.T) {
+	devs := []string{"a", "b", "c", "d"}
+
+	cases := []struct {
+		name  string
+		start int
+		size  int
+		want  []string
+	}{
+		{name: "simple window", start: 0, size: 2, want: []string{"a", "b"}},
+		{name: "wraps around", start: 3, size: 2, want: []string{"d", "a"}},
+		{name: "negative start wraps", start: -1, size: 1, want: []string{"d"}},
+		{name: "size clamped to len(devs)", start: 0, size: 10, want: []string{"a", "b", "c", "d"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rotateSlice(devs, tc.start, tc.size)
+			if len(got) != len(tc.want) {
+				t.Fatalf("rotateSlice() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("rotateSlice() = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDateIndex(t *testing.T) {
+	if got := dateIndex("2026-07-27", 5); got < 0 || got >= 5 {
+		t.Fatalf("dateIndex() = %d, want in [0, 5)", got)
+	}
+
+	if dateIndex("2026-07-27", 5) != dateIndex("2026-07-27", 5) {
+		t.Fatal("dateIndex() is not stable across calls for the same date")
+	}
+}
+
+func TestWeightedSelectExcludesZeroWeight(t *testing.T) {
+	devs := []string{"alovelace", "ghopper", "mhopper"}
+	weights := map[string]float64{"ghopper": 0}
+
+	got := weightedSelect(devs, weights, "2026-07-27", 3)
+
+	if len(got) != 2 {
+		t.Fatalf("weightedSelect() = %v, want exactly the 2 non-excluded devs", got)
+	}
+	for _, dv := range got {
+		if dv == "ghopper" {
+			t.Fatalf("weightedSelect() selected %q despite its explicit weight of 0", dv)
+		}
+	}
+}
+
+func TestWeightedSelectMissingWeightDefaultsToOne(t *testing.T) {
+	devs := []string{"alovelace", "ghopper"}
+
+	withNoWeights := weightedSelect(devs, nil, "2026-07-27", 1)
+	withExplicitOne := weightedSelect(devs, map[string]float64{"alovelace": 1, "ghopper": 1}, "2026-07-27", 1)
+
+	if len(withNoWeights) != 1 || len(withExplicitOne) != 1 || withNoWeights[0] != withExplicitOne[0] {
+		t.Fatalf("weightedSelect() with missing weights = %v, want same as all-1 weights %v", withNoWeights, withExplicitOne)
+	}
+}
+
+func TestWeightedSelectIsDeterministic(t *testing.T) {
+	devs := []string{"alovelace", "ghopper", "mhopper", "agrant"}
+	weights := map[string]float64{"ghopper": 2}
+
+	first := weightedSelect(devs, weights, "2026-07-27", 2)
+	second := weightedSelect(devs, weights, "2026-07-27", 2)
+
+	if len(first) != len(second) {
+		t.Fatalf("weightedSelect() = %v, %v; want identical results for the same date", first, second)
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("weightedSelect() = %v, %v; want identical results for the same date", first, second)
+		}
+	}
+}
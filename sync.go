@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pkg/errors"
+)
+
+// devProposal is a dev discovered while scanning git history that
+// isn't already present (by email) in the data's Devs map.
+type devProposal struct {
+	id    string
+	name  string
+	email string
+}
+
+func (p devProposal) String() string {
+	return fmt.Sprintf("%s: %s <%s>", p.id, p.name, p.email)
+}
+
+// syncDevs scans repoPath's commit history for author identities and
+// returns the devs that would be newly added to d.Devs, without
+// mutating d. Call applySync with the result to actually write them.
+// Existing entries (matched by email) are left untouched so manual
+// overrides already in the YAML survive a re-sync.
+func (d *data) syncDevs(repoPath string) ([]devProposal, error) {
+	authors, err := logAuthors(repoPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "log authors for %s failed", repoPath)
+	}
+
+	return syncProposalsFromAuthors(d, authors), nil
+}
+
+// syncProposalsFromAuthors is the proposal-building half of syncDevs,
+// split out so it can be unit tested against a fixed author list
+// without a real git repo to scan.
+func syncProposalsFromAuthors(d *data, authors []gitAuthor) []devProposal {
+	existingEmails := make(map[string]bool)
+	existingIDs := make(map[string]bool)
+	for id, dv := range d.Devs {
+		existingEmails[dv.Email] = true
+		existingIDs[id] = true
+	}
+
+	var (
+		proposals []devProposal
+		seen      = make(map[string]bool)
+	)
+
+	for _, a := range authors {
+		if existingEmails[a.email] || seen[a.email] {
+			continue
+		}
+		seen[a.email] = true
+
+		id := uniqueDevID(shortDevID(a.name), existingIDs)
+		existingIDs[id] = true
+
+		proposals = append(proposals, devProposal{id: id, name: a.name, email: a.email})
+	}
+
+	return proposals
+}
+
+// applySync adds each proposal to d.Devs, keyed by its assigned id.
+func (d *data) applySync(proposals []devProposal) {
+	for _, p := range proposals {
+		d.addDev(p.id, p.name, p.email)
+	}
+}
+
+type gitAuthor struct {
+	name  string
+	email string
+}
+
+// logAuthors walks repoPath's commit history via go-git and returns
+// the author identities it finds, most recent commit first. Reading
+// the structured Author.Name/Email off each commit object, rather
+// than shelling out to `git log` and re-parsing its text output,
+// keeps this independent of the hooks git happens to format identity
+// lines with.
+func logAuthors(repoPath string) ([]gitAuthor, error) {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "open repo %s failed", repoPath)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve HEAD failed")
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, errors.Wrap(err, "walk commit log failed")
+	}
+
+	var authors []gitAuthor
+	err = commits.ForEach(func(c *object.Commit) error {
+		authors = append(authors, gitAuthor{name: c.Author.Name, email: c.Author.Email})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "iterate commit log failed")
+	}
+
+	return authors, nil
+}
+
+var nonDevIDCharsRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// shortDevID derives a candidate dev id from a full name, e.g.
+// "Ada Lovelace" -> "alovelace", following the first-initial +
+// lastname convention common in CONTRIBUTORS-style tooling.
+func shortDevID(name string) string {
+	parts := strings.Fields(strings.ToLower(name))
+
+	switch len(parts) {
+	case 0:
+		return "dev"
+	case 1:
+		return nonDevIDCharsRegexp.ReplaceAllString(parts[0], "")
+	default:
+		id := parts[0][:1] + parts[len(parts)-1]
+		return nonDevIDCharsRegexp.ReplaceAllString(id, "")
+	}
+}
+
+// uniqueDevID appends a numeric suffix to id until it no longer
+// collides with an id already in taken.
+func uniqueDevID(id string, taken map[string]bool) string {
+	if !taken[id] {
+		return id
+	}
+
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s%d", id, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// diffSync renders proposals as a human-readable preview of what a
+// sync would add, one line per dev, sorted by id for stable output.
+func diffSync(proposals []devProposal) string {
+	sorted := make([]devProposal, len(proposals))
+	copy(sorted, proposals)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].id < sorted[j].id })
+
+	var b strings.Builder
+	for _, p := range sorted {
+		fmt.Fprintf(&b, "+ %s\n", p)
+	}
+	return b.String()
+}
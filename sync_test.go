@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestShortDevID(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{name: "Ada Lovelace", want: "alovelace"},
+		{name: "Madonna", want: "madonna"},
+		{name: "Grace  Brewster Hopper", want: "ghopper"},
+		{name: "", want: "dev"},
+	}
+
+	for _, tc := range cases {
+		got := shortDevID(tc.name)
+		if got != tc.want {
+			t.Errorf("shortDevID(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUniqueDevID(t *testing.T) {
+	taken := map[string]bool{"alovelace": true, "alovelace2": true}
+
+	got := uniqueDevID("alovelace", taken)
+	want := "alovelace3"
+	if got != want {
+		t.Errorf("uniqueDevID() = %q, want %q", got, want)
+	}
+
+	if got := uniqueDevID("mhopper", taken); got != "mhopper" {
+		t.Errorf("uniqueDevID() on a free id = %q, want %q", got, "mhopper")
+	}
+}
+
+func TestSyncDevsPreservesExistingEntries(t *testing.T) {
+	d := &data{}
+	d.addDev("alovelace", "Ada Lovelace", "ada@example.com")
+
+	proposals := syncProposalsFromAuthors(d, []gitAuthor{
+		{name: "Ada Lovelace", email: "ada@example.com"},
+		{name: "Grace Hopper", email: "grace@example.com"},
+		{name: "Grace Hopper", email: "grace@example.com"},
+	})
+
+	if len(proposals) != 1 {
+		t.Fatalf("proposals = %v, want exactly one new dev", proposals)
+	}
+	if proposals[0].email != "grace@example.com" {
+		t.Errorf("proposed email = %q, want %q", proposals[0].email, "grace@example.com")
+	}
+	if proposals[0].id == "alovelace" {
+		t.Errorf("proposed id collided with an existing dev id: %q", proposals[0].id)
+	}
+}
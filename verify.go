@@ -0,0 +1,190 @@
+package main
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// defaultVerifyRange is used when the caller doesn't specify a range,
+// mirroring how code review tools default to what's new on a branch.
+const defaultVerifyRange = "@{upstream}..HEAD"
+
+// verifyRange checks every commit in gitRange (empty defaults to
+// defaultVerifyRange) against the repo's declared devs: each
+// Co-authored-by trailer must name a dev from repo.Devs, and, when
+// requireSigned is true, the commit must carry a GPG signature whose
+// key fingerprint belongs to one of those devs.
+func (d *data) verifyRange(wd, gitRange string, requireSigned bool) error {
+	_, repo := d.lookupRepo(wd)
+	if repo == nil {
+		return errors.Errorf("no repo with path %s found", wd)
+	}
+
+	if gitRange == "" {
+		gitRange = defaultVerifyRange
+	}
+
+	commits, err := logCommits(wd, gitRange)
+	if err != nil {
+		return errors.Wrapf(err, "log commits for %s failed", wd)
+	}
+
+	for _, c := range commits {
+		if err := d.verifyCommit(repo, c, requireSigned); err != nil {
+			return errors.Wrapf(err, "commit %s failed verification", c.hash)
+		}
+	}
+
+	return nil
+}
+
+func (d *data) verifyCommit(repo *repo, c commitInfo, requireSigned bool) error {
+	for _, email := range c.coAuthorEmails {
+		if !d.repoDevByEmail(repo, email) {
+			return errors.Errorf("co-author %s is not a declared dev for this repo", email)
+		}
+	}
+
+	if !requireSigned {
+		return nil
+	}
+
+	if c.gpgKeyID == "" || !c.gpgGood {
+		return errors.New("commit has no valid GPG signature")
+	}
+
+	for _, devID := range repo.Devs {
+		dv := d.lookupDev(devID)
+		if dv != nil && dv.GPGKey != "" && strings.EqualFold(dv.GPGKey, c.gpgKeyID) {
+			return nil
+		}
+	}
+
+	return errors.Errorf("signing key %s does not belong to a declared dev", c.gpgKeyID)
+}
+
+// repoDevByEmail reports whether email belongs to one of repo's
+// declared devs.
+func (d *data) repoDevByEmail(repo *repo, email string) bool {
+	for _, devID := range repo.Devs {
+		dv := d.lookupDev(devID)
+		if dv != nil && dv.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// commitInfo is the subset of `git log --show-signature` output that
+// verifyCommit needs for a single commit. gpgGood is only trustworthy
+// together with a non-empty gpgKeyID: the "using ... key" line and the
+// verdict line are reported separately by git, and a tampered commit
+// can still carry a stale "using" line even though its verdict is
+// "BAD signature" or "Can't check signature".
+type commitInfo struct {
+	hash           string
+	coAuthorEmails []string
+	gpgKeyID       string
+	gpgGood        bool
+}
+
+var gpgKeyIDRegexp = regexp.MustCompile(`[0-9A-Fa-f]{8,40}$`)
+
+// applySignatureLine folds one line of `git log --show-signature`
+// output belonging to c's commit into c. Split out of logCommits so
+// the key-id/verdict parsing can be unit tested without a real git
+// binary or a signed commit.
+func applySignatureLine(c *commitInfo, line string) {
+	switch {
+	case strings.HasPrefix(line, "Co-authored-by:"):
+		_, email := nameEmail(line)
+		c.coAuthorEmails = append(c.coAuthorEmails, email)
+
+	case strings.Contains(line, "gpg:") && strings.Contains(line, "using"):
+		c.gpgKeyID = gpgKeyIDRegexp.FindString(line)
+
+	case strings.Contains(line, "gpg: Good signature"):
+		c.gpgGood = true
+
+	case strings.Contains(line, "gpg: BAD signature"),
+		strings.Contains(line, "gpg: Can't check signature"),
+		strings.Contains(line, "gpg: no signature"):
+		c.gpgGood = false
+	}
+}
+
+// logRecordSep and logFieldSep delimit logCommits' --format output:
+// logFieldSep separates a commit's hash from its raw body, and
+// logRecordSep separates one commit's whole record from the next.
+// Both are control characters that can't appear in a hash or a commit
+// message, so splitting on them is unambiguous.
+const (
+	logRecordSep = "\x00"
+	logFieldSep  = "\x01"
+)
+
+// logCommits shells out to `git log --show-signature` for gitRange
+// and parses out each commit's hash, Co-authored-by emails, and
+// whether it carries a GPG signature git itself reports as good (as
+// opposed to merely present). It shells out via exec.Command rather
+// than using go-git, since go-git doesn't expose signature
+// verification.
+//
+// git's --show-signature prints a commit's gpg: verification block
+// *before* that commit's own --format output, not after, so the gpg
+// lines for commit N arrive ahead of commit N's hash. logCommits
+// exploits that ordering directly: it buffers whatever lines precede
+// a record's hash and attributes them to the hash that follows.
+func logCommits(wd, gitRange string) ([]commitInfo, error) {
+	format := "--format=%H" + logFieldSep + "%B" + logRecordSep
+	cmd := exec.Command("git", "log", "--show-signature", format, gitRange)
+	cmd.Dir = wd
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "git log failed")
+	}
+
+	return parseLogCommits(string(output)), nil
+}
+
+// parseLogCommits parses the raw output of `git log --show-signature
+// --format="%H\x01%B\x00"` into one commitInfo per commit. Split out
+// of logCommits so the record-boundary/ordering logic can be unit
+// tested against real `git log --show-signature` output, not just
+// applySignatureLine in isolation.
+func parseLogCommits(output string) []commitInfo {
+	var commits []commitInfo
+
+	for _, record := range strings.Split(output, logRecordSep) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		head, body, ok := strings.Cut(record, logFieldSep)
+		if !ok {
+			continue
+		}
+
+		// head is the gpg verification lines for this commit (if any)
+		// followed by its hash on the last line.
+		headLines := strings.Split(head, "\n")
+		hash := headLines[len(headLines)-1]
+		sigLines := headLines[:len(headLines)-1]
+
+		c := &commitInfo{hash: hash}
+		for _, line := range sigLines {
+			applySignatureLine(c, line)
+		}
+		for _, line := range strings.Split(body, "\n") {
+			applySignatureLine(c, line)
+		}
+
+		commits = append(commits, *c)
+	}
+
+	return commits
+}
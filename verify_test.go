@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplySignatureLine(t *testing.T) {
+	cases := []struct {
+		name     string
+		lines    []string
+		wantKey  string
+		wantGood bool
+	}{
+		{
+			name: "good signature",
+			lines: []string{
+				"gpg: Signature made Mon 27 Jul 2026",
+				"gpg:                using RSA key ABCDEF0123456789",
+				"gpg: Good signature from \"Ada Lovelace <ada@example.com>\" [ultimate]",
+			},
+			wantKey:  "ABCDEF0123456789",
+			wantGood: true,
+		},
+		{
+			name: "tampered commit keeps the using line but fails the verdict",
+			lines: []string{
+				"gpg: Signature made Mon 27 Jul 2026",
+				"gpg:                using RSA key ABCDEF0123456789",
+				"gpg: BAD signature from \"Ada Lovelace <ada@example.com>\"",
+			},
+			wantKey:  "ABCDEF0123456789",
+			wantGood: false,
+		},
+		{
+			name: "cannot check signature",
+			lines: []string{
+				"gpg:                using RSA key ABCDEF0123456789",
+				"gpg: Can't check signature: No public key",
+			},
+			wantKey:  "ABCDEF0123456789",
+			wantGood: false,
+		},
+		{
+			name:     "no signature at all",
+			lines:    []string{"this commit has no gpg lines"},
+			wantKey:  "",
+			wantGood: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &commitInfo{hash: "deadbeef"}
+			for _, line := range tc.lines {
+				applySignatureLine(c, line)
+			}
+
+			if c.gpgKeyID != tc.wantKey {
+				t.Errorf("gpgKeyID = %q, want %q", c.gpgKeyID, tc.wantKey)
+			}
+			if c.gpgGood != tc.wantGood {
+				t.Errorf("gpgGood = %v, want %v", c.gpgGood, tc.wantGood)
+			}
+		})
+	}
+}
+
+// TestParseLogCommitsSignatureOrder exercises the shape of real
+// `git log --show-signature --format="%H\x01%B\x00"` output, where
+// each commit's gpg: verification block is printed *before* that
+// commit's own formatted fields, not after. It's a regression test
+// for a bug where the newest commit's signature lines were dropped
+// and every other commit's signature was attributed to the previous
+// (newer) commit.
+func TestParseLogCommitsSignatureOrder(t *testing.T) {
+	output := strings.Join([]string{
+		"gpg:                using RSA key ABCDEF0123456789",
+		"gpg: Good signature from \"Ada Lovelace <ada@example.com>\" [ultimate]",
+		"newesthash" + logFieldSep + "Add the signing docs\n\nCo-authored-by: Ada Lovelace <ada@example.com>\n" + logRecordSep,
+		"gpg:                using RSA key FEDCBA9876543210",
+		"gpg: BAD signature from \"Grace Hopper <grace@example.com>\"",
+		"oldesthash" + logFieldSep + "Initial commit\n" + logRecordSep,
+	}, "\n")
+
+	commits := parseLogCommits(output)
+	if len(commits) != 2 {
+		t.Fatalf("parseLogCommits() returned %d commits, want 2", len(commits))
+	}
+
+	newest, oldest := commits[0], commits[1]
+
+	if newest.hash != "newesthash" {
+		t.Fatalf("commits[0].hash = %q, want %q", newest.hash, "newesthash")
+	}
+	if newest.gpgKeyID != "ABCDEF0123456789" || !newest.gpgGood {
+		t.Errorf("newest commit = %+v, want a good signature from ABCDEF0123456789", newest)
+	}
+	if len(newest.coAuthorEmails) != 1 || newest.coAuthorEmails[0] != "ada@example.com" {
+		t.Errorf("newest commit coAuthorEmails = %v, want [ada@example.com]", newest.coAuthorEmails)
+	}
+
+	if oldest.hash != "oldesthash" {
+		t.Fatalf("commits[1].hash = %q, want %q", oldest.hash, "oldesthash")
+	}
+	if oldest.gpgKeyID != "FEDCBA9876543210" || oldest.gpgGood {
+		t.Errorf("oldest commit = %+v, want a BAD signature from FEDCBA9876543210", oldest)
+	}
+}
+
+func TestVerifyCommitRejectsBadSignature(t *testing.T) {
+	d := &data{}
+	d.addDev("ada", "Ada Lovelace", "ada@example.com")
+
+	r := &repo{Devs: []string{"ada"}}
+
+	tampered := commitInfo{
+		hash:     "deadbeef",
+		gpgKeyID: "ABCDEF0123456789",
+		gpgGood:  false,
+	}
+	d.Devs["ada"].GPGKey = "ABCDEF0123456789"
+
+	if err := d.verifyCommit(r, tampered, true); err == nil {
+		t.Fatal("verifyCommit accepted a commit with a BAD signature verdict")
+	}
+
+	good := tampered
+	good.gpgGood = true
+
+	if err := d.verifyCommit(r, good, true); err != nil {
+		t.Fatalf("verifyCommit rejected a genuinely good signature: %v", err)
+	}
+}
@@ -13,9 +13,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
+	"github.com/souravbadami/xp/identity"
 )
 
 type data struct {
@@ -57,6 +59,13 @@ func (d *data) store(w io.Writer) error {
 type dev struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// GPGKey is this dev's GPG signing key, used by `xp verify -signed`
+	// to accept a commit signed with it in any repo that declares this
+	// dev. It must equal (case-insensitively) the key id or fingerprint
+	// gpg reports for the signature; a shorter form of the same key does
+	// not match. It is not matched against the devs the commit's
+	// Co-authored-by trailers name.
+	GPGKey string `json:"gpgKey,omitempty"`
 }
 
 func (d *dev) String() string {
@@ -80,6 +89,15 @@ func (d *data) lookupDev(id string) *dev {
 type repo struct {
 	Devs    []string `json:"devs"`
 	IssueID string   `json:"issueId"`
+	// Tracker selects the issue-reference style to recognize and
+	// canonicalize in commit messages: "github" (#123), "jira"
+	// (PROJ-45) or "gitlab" (!123). Empty recognizes GitHub-style
+	// references and renders numeric ids as #123, leaving other ids
+	// as-is.
+	Tracker string `json:"tracker"`
+	// Rotation, if set, picks a subset of Devs to co-author each
+	// commit instead of all of them. See repoRotation.
+	Rotation *repoRotation `json:"rotation,omitempty"`
 }
 
 func (d *data) validateDevs(devIDs []string) error {
@@ -91,7 +109,7 @@ func (d *data) validateDevs(devIDs []string) error {
 	return nil
 }
 
-func (d *data) addRepo(path string, devIDs []string, issueID string) error {
+func (d *data) addRepo(path string, devIDs []string, issueID, tracker string) error {
 	if d.Repos == nil {
 		d.Repos = make(map[string]*repo)
 	}
@@ -103,57 +121,245 @@ func (d *data) addRepo(path string, devIDs []string, issueID string) error {
 	d.Repos[path] = &repo{
 		Devs:    devIDs,
 		IssueID: issueID,
+		Tracker: tracker,
 	}
 
 	return nil
 }
 
+// initRepo installs xp's hooks for the repo at pathStr, chaining onto
+// any pre-existing prepare-commit-msg/commit-msg hooks instead of
+// clobbering them, and honoring core.hooksPath and worktree layouts
+// (where .git is a file, not a directory) via resolveHooksDir.
+// Reinstalling only ever rewrites xp's own marker block inside a
+// hook, so a foreign hook it was chained onto is never lost.
 func initRepo(pathStr string, overwrite bool, xpBinPath string) error {
-	gitPath := path.Join(pathStr, ".git")
+	hooksDir, err := resolveHooksDir(pathStr)
+	if err != nil {
+		return err
+	}
 
-	if _, err := os.Stat(gitPath); err != nil {
-		return errors.Wrapf(err, ".git not found in %s", pathStr)
+	for _, name := range hookNames {
+		if err := installHook(path.Join(hooksDir, name), xpBinPath, overwrite); err != nil {
+			return err
+		}
 	}
 
-	if !overwrite {
-		for _, hookFile := range hookFiles {
-			if _, err := os.Stat(path.Join(gitPath, hookFile)); err == nil {
-				// TODO: Check if it is our prepare-commit-msg hook.
-				return errors.Errorf("%s is already defined", hookFile)
-			}
+	return nil
+}
+
+// initTemplateDir installs xp's hooks into git's global
+// init.templateDir (setting it up if unset) instead of a specific
+// repo, so every future `git init`/`git clone` auto-enrolls.
+func initTemplateDir(xpBinPath string) error {
+	out, err := exec.Command("git", "config", "--global", "--get", "init.templateDir").Output()
+	templateDir := strings.TrimSpace(string(out))
+
+	if err != nil || templateDir == "" {
+		home, herr := os.UserHomeDir()
+		if herr != nil {
+			return errors.Wrap(herr, "resolve home dir failed")
+		}
+		templateDir = path.Join(home, ".git-templates")
+
+		if err := exec.Command("git", "config", "--global", "init.templateDir", templateDir).Run(); err != nil {
+			return errors.Wrap(err, "set init.templateDir failed")
 		}
 	}
 
-	hookStr := fmt.Sprintf(hookStrTmpl, xpBinPath)
+	hooksDir := path.Join(templateDir, "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return errors.Wrapf(err, "create %s failed", hooksDir)
+	}
 
-	for _, hookFile := range hookFiles {
-		hookFile = path.Join(gitPath, hookFile)
+	for _, name := range hookNames {
+		if err := installHook(path.Join(hooksDir, name), xpBinPath, true); err != nil {
+			return err
+		}
+	}
 
-		f, err := os.OpenFile(hookFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
-		if err != nil {
-			return errors.Wrapf(err, "create hook file %s failed", hookFile)
+	return nil
+}
+
+// uninstallRepo removes only the xp-managed block from each of repo's
+// hook files, leaving any foreign hook content it was chained onto
+// intact. A hook file with no xp block, or none at all, is untouched.
+func uninstallRepo(pathStr string) error {
+	hooksDir, err := resolveHooksDir(pathStr)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range hookNames {
+		if err := uninstallHook(path.Join(hooksDir, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var hookNames = []string{
+	"prepare-commit-msg",
+	"commit-msg",
+}
+
+const (
+	hookMarkerBegin = "# >>> xp managed block >>>"
+	hookMarkerEnd   = "# <<< xp managed block <<<"
+)
+
+// xpHookBlock renders the marker-delimited block xp installs into a
+// hook file. Content outside the markers (a pre-existing hook's own
+// commands) survives a reinstall untouched.
+func xpHookBlock(xpBinPath string) string {
+	return fmt.Sprintf("%s\n%s add-info \"$1\"\n%s\n", hookMarkerBegin, xpBinPath, hookMarkerEnd)
+}
+
+// installHook writes xp's managed block into hookFile. A file that
+// already carries an xp block just has that block refreshed in
+// place. A foreign, non-empty hook requires overwrite to adopt, and
+// is then chained onto (xp's block runs after it) rather than
+// replaced. A missing or empty file gets a fresh shebang plus block.
+func installHook(hookFile, xpBinPath string, overwrite bool) error {
+	existing, err := ioutil.ReadFile(hookFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "read hook file %s failed", hookFile)
+	}
+
+	body := string(existing)
+	block := xpHookBlock(xpBinPath)
+
+	switch {
+	case hasXPBlock(body):
+		body = replaceXPBlock(body, block)
+
+	case strings.TrimSpace(body) != "":
+		if !overwrite {
+			return errors.Errorf("%s already exists and is not managed by xp; rerun with overwrite to chain onto it", hookFile)
 		}
+		body = ensureShebang(body) + "\n" + block
+
+	default:
+		body = "#!/bin/sh\n\n" + block
+	}
+
+	if err := ioutil.WriteFile(hookFile, []byte(body), 0755); err != nil {
+		return errors.Wrapf(err, "write hook file %s failed", hookFile)
+	}
+
+	return nil
+}
 
-		if _, err := f.WriteString(hookStr); err != nil {
-			return errors.Wrap(err, "write hook content failed")
+// uninstallHook strips xp's managed block out of hookFile. If nothing
+// but the block (and a bare shebang) remains, the file is removed
+// entirely; otherwise the surrounding foreign hook is left in place.
+func uninstallHook(hookFile string) error {
+	existing, err := ioutil.ReadFile(hookFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
 		}
+		return errors.Wrapf(err, "read hook file %s failed", hookFile)
+	}
 
-		if err := f.Close(); err != nil {
-			return errors.Wrap(err, "close hook file failed")
+	body := string(existing)
+	if !hasXPBlock(body) {
+		return nil
+	}
+
+	remaining := strings.TrimSpace(replaceXPBlock(body, ""))
+	if remaining == "" || remaining == "#!/bin/sh" {
+		if err := os.Remove(hookFile); err != nil {
+			return errors.Wrapf(err, "remove hook file %s failed", hookFile)
 		}
+		return nil
+	}
+
+	if err := ioutil.WriteFile(hookFile, []byte(remaining+"\n"), 0755); err != nil {
+		return errors.Wrapf(err, "write hook file %s failed", hookFile)
 	}
 
 	return nil
 }
 
-var hookFiles = []string{
-	"hooks/prepare-commit-msg",
-	"hooks/commit-msg",
+func hasXPBlock(body string) bool {
+	return strings.Contains(body, hookMarkerBegin) && strings.Contains(body, hookMarkerEnd)
 }
 
-var hookStrTmpl = `#!/bin/sh
-%s add-info $1
-`
+// replaceXPBlock swaps out the xp-managed block in body for block
+// (pass "" to delete it), leaving everything else untouched.
+func replaceXPBlock(body, block string) string {
+	start := strings.Index(body, hookMarkerBegin)
+	end := strings.Index(body, hookMarkerEnd) + len(hookMarkerEnd)
+	return body[:start] + strings.TrimSuffix(block, "\n") + body[end:]
+}
+
+// ensureShebang guarantees body starts with a shebang line, so a
+// foreign hook we're chaining onto still runs under a shell once xp's
+// block is appended to it.
+func ensureShebang(body string) string {
+	if strings.HasPrefix(body, "#!") {
+		return strings.TrimRight(body, "\n")
+	}
+	return "#!/bin/sh\n\n" + strings.TrimRight(body, "\n")
+}
+
+// resolveHooksDir returns the directory hooks should be installed
+// into for the repo at pathStr, honoring `git config core.hooksPath`
+// and worktree checkouts where .git is a file pointing elsewhere.
+func resolveHooksDir(pathStr string) (string, error) {
+	gitDir, err := resolveGitDir(pathStr)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := exec.Command("git", "-C", pathStr, "config", "--get", "core.hooksPath").Output()
+	if err == nil {
+		if hooksPath := strings.TrimSpace(string(out)); hooksPath != "" {
+			if path.IsAbs(hooksPath) {
+				return hooksPath, nil
+			}
+			return path.Join(pathStr, hooksPath), nil
+		}
+	}
+
+	return path.Join(gitDir, "hooks"), nil
+}
+
+// resolveGitDir returns the real .git directory for pathStr,
+// following a worktree's .git file (which contains "gitdir: <path>")
+// instead of assuming .git itself is the directory.
+func resolveGitDir(pathStr string) (string, error) {
+	gitPath := path.Join(pathStr, ".git")
+
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return "", errors.Wrapf(err, ".git not found in %s", pathStr)
+	}
+
+	if info.IsDir() {
+		return gitPath, nil
+	}
+
+	contents, err := ioutil.ReadFile(gitPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s failed", gitPath)
+	}
+
+	const gitdirPrefix = "gitdir: "
+	line := strings.TrimSpace(string(contents))
+	if !strings.HasPrefix(line, gitdirPrefix) {
+		return "", errors.Errorf("unrecognized .git file contents in %s", pathStr)
+	}
+
+	gitDir := strings.TrimPrefix(line, gitdirPrefix)
+	if !path.IsAbs(gitDir) {
+		gitDir = path.Join(pathStr, gitDir)
+	}
+
+	return gitDir, nil
+}
 
 func (d *data) lookupRepo(pathStr string) (string, *repo) {
 	if d.Repos == nil {
@@ -202,12 +408,11 @@ func (d *data) appendInfo(wd, msgFile string) error {
 		return errors.Errorf("no repo with path %s found", wd)
 	}
 
-	// GIT_COMMITTER_IDENT can be used to get committer info.
-	author, err := gitVar("GIT_AUTHOR_IDENT")
+	author, err := identity.Resolve(wd)
 	if err != nil {
 		return errors.Wrap(err, "get author info failed")
 	}
-	authorName, authorEmail := nameEmail(author)
+	authorName, authorEmail := author.Name, author.Email
 
 	msg, err := ioutil.ReadFile(msgFile)
 	if err != nil {
@@ -217,11 +422,13 @@ func (d *data) appendInfo(wd, msgFile string) error {
 	var (
 		msgStr = string(msg)
 
-		devs    = make(map[string]*dev)
-		edevs   = existingDevs(msgStr)
-		issueID = existingIssueID(msgStr)
+		devs     = make(map[string]*dev)
+		edevs    = existingDevs(msgStr)
+		issueIDs = existingIssueIDs(msgStr)
 	)
 
+	issueIDs = append(issueIDs, scanIssueRefs(msgStr, repo.Tracker)...)
+
 	for _, dev := range edevs {
 		devs[dev.Email] = dev
 	}
@@ -240,18 +447,23 @@ func (d *data) appendInfo(wd, msgFile string) error {
 
 			if i == 0 && issueIDRegexp.MatchString(id) {
 				// We will assume the the first id (if not a dev)
-				// is the issue id.
-				issueID = id
+				// is an issue id.
+				issueIDs = append(issueIDs, id)
 				continue
 			}
 			return errors.Errorf("non-existing dev %s provided in the first line", id)
 		}
 	}
 
+	for i, id := range issueIDs {
+		issueIDs[i] = canonicalIssueID(repo.Tracker, id)
+	}
+	issueIDs = dedupeIssueIDs(issueIDs)
+
 	// We only look at repo devs if both existing and first line devs
 	// are not specifying any devs.
 	if len(devs) == 0 {
-		for _, devID := range repo.Devs {
+		for _, devID := range selectRotation(repo, time.Now().Format("2006-01-02")) {
 			dev := d.lookupDev(devID)
 			if dev == nil {
 				return errors.Errorf("non-existing dev %s marked as working for repo %s", devID, repoPath)
@@ -293,12 +505,8 @@ func (d *data) appendInfo(wd, msgFile string) error {
 
 	fmt.Fprintf(f, "\n\n")
 
-	if issueID != "" {
-		if _, err := strconv.Atoi(issueID); err == nil {
-			fmt.Fprintf(f, "%s#%s\n\n", issueIDPrefix, issueID)
-		} else {
-			fmt.Fprintf(f, "%s%s\n\n", issueIDPrefix, issueID)
-		}
+	for _, issueID := range issueIDs {
+		fmt.Fprintf(f, "%s%s\n\n", issueIDPrefix, issueID)
 	}
 
 	// We will write the authors back sorted by their email.
@@ -327,6 +535,82 @@ func (d *data) appendInfo(wd, msgFile string) error {
 
 var issueIDRegexp = regexp.MustCompile("#?.*[0-9]+")
 
+// issueCloseKeywordsPat matches a close/fix/resolve keyword (in any
+// tense, optionally followed by a colon) together with whatever
+// references follow it up to the end of the line, mirroring the
+// IssueCloseKeywordsPat/IssueReferenceKeywordsPat split Gogs/Gitea
+// use to detect issue-closing commits. The \b anchors keep it from
+// matching inside ordinary words like "Disclose" or "foreclosed".
+var issueCloseKeywordsPat = regexp.MustCompile(
+	`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\b\s*:?\s+([^\n]+)`,
+)
+
+// issueRefPatterns holds the reference-token pattern recognized for
+// each supported tracker style. "github" is also the fallback used
+// when a repo has no Tracker configured.
+var issueRefPatterns = map[string]*regexp.Regexp{
+	"github": regexp.MustCompile(`#\d+`),
+	"jira":   regexp.MustCompile(`[A-Z][A-Z0-9]+-\d+`),
+	"gitlab": regexp.MustCompile(`![0-9]+`),
+}
+
+// scanIssueRefs scans msg for close/fix/resolve keywords and returns
+// the issue ids referenced right after them, in first-seen order.
+// Which reference token is recognized (GitHub #123, JIRA PROJ-45, or
+// GitLab !123) is controlled by tracker; an unknown or empty tracker
+// falls back to the GitHub style.
+func scanIssueRefs(msg, tracker string) []string {
+	refPat, ok := issueRefPatterns[tracker]
+	if !ok {
+		refPat = issueRefPatterns["github"]
+	}
+
+	var ids []string
+	for _, m := range issueCloseKeywordsPat.FindAllStringSubmatch(msg, -1) {
+		ids = append(ids, refPat.FindAllString(m[1], -1)...)
+	}
+
+	return ids
+}
+
+// canonicalIssueID normalizes a raw issue reference (scanned from a
+// commit message or an existing trailer) into the canonical form for
+// the repo's tracker style. With no tracker configured it falls back
+// to the legacy behaviour: numeric ids are rendered GitHub-style,
+// anything else (e.g. a JIRA key) is left as-is.
+func canonicalIssueID(tracker, raw string) string {
+	raw = strings.TrimSpace(raw)
+
+	switch tracker {
+	case "jira", "gitlab":
+		return raw
+
+	case "github":
+		return "#" + strings.TrimPrefix(raw, "#")
+
+	default:
+		if _, err := strconv.Atoi(strings.TrimPrefix(raw, "#")); err == nil {
+			return "#" + strings.TrimPrefix(raw, "#")
+		}
+		return raw
+	}
+}
+
+// dedupeIssueIDs removes duplicate issue ids while preserving the
+// order in which they were first seen.
+func dedupeIssueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
 func firstLineIDs(msg string) ([]string, int) {
 	if len(msg) == 0 {
 		return nil, 0
@@ -362,27 +646,28 @@ func firstLineIDs(msg string) ([]string, int) {
 	return nil, 0
 }
 
-var gitVar = func(varStr string) (string, error) {
-	output, err := exec.Command("git", "var", varStr).Output()
-	if err != nil {
-		return "", errors.Wrap(err, "git exec failed")
-	}
-	return string(output), nil
-}
-
+// nameEmailRegexp matches a "Name <email>" fragment, optionally
+// preceded by a "label: " prefix (e.g. "Co-authored-by: "), anchoring
+// on the trailing "<email>" rather than the position of the first
+// ":" or "<" in the string. That anchoring is what lets it parse
+// "Co-authored-by: O'Brien: Smith <bob@example.com>" correctly, where
+// scanning for the first ":" or "<" would cut the name short.
+var nameEmailRegexp = regexp.MustCompile(`^(?:[^:<]+:\s*)?(.*)\s<([^<>]*)>\s*$`)
+
+// nameEmail parses a "Name <email>" (optionally "label: Name <email>")
+// fragment out of a trailer or git-log line, e.g. "Co-authored-by:
+// Ada Lovelace <ada@example.com>".
 func nameEmail(ident string) (string, string) {
-	idx := strings.Index(ident, "<")
-	colonIdx := strings.Index(ident, ":")
-	nameStart := 0
-	if colonIdx != -1 && colonIdx < idx {
-		nameStart = colonIdx + 2
+	m := nameEmailRegexp.FindStringSubmatch(strings.TrimSpace(ident))
+	if m == nil {
+		return "", ""
 	}
-	name := ident[nameStart : idx-1]
-	email := ident[idx+1 : strings.Index(ident, ">")]
-	return name, email
+	return m[1], m[2]
 }
 
-func existingIssueID(msg string) string {
+func existingIssueIDs(msg string) []string {
+	var ids []string
+
 	scanner := bufio.NewScanner(strings.NewReader(msg))
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -393,11 +678,11 @@ func existingIssueID(msg string) string {
 
 		issueID := line[len(issueIDPrefix):]
 		if issueIDRegexp.MatchString(issueID) {
-			return issueID
+			ids = append(ids, issueID)
 		}
 	}
 
-	return ""
+	return ids
 }
 
 func existingDevs(msg string) []*dev {
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanIssueRefs(t *testing.T) {
+	cases := []struct {
+		name    string
+		msg     string
+		tracker string
+		want    []string
+	}{
+		{
+			name: "github close keyword",
+			msg:  "Fix the login bug\n\nFixes #12 and closes #13",
+			want: []string{"#12", "#13"},
+		},
+		{
+			name:    "jira close keyword",
+			msg:     "Fixes PROJ-45",
+			tracker: "jira",
+			want:    []string{"PROJ-45"},
+		},
+		{
+			name:    "gitlab close keyword",
+			msg:     "closes !7",
+			tracker: "gitlab",
+			want:    []string{"!7"},
+		},
+		{
+			name: "word inside another word is not a keyword",
+			msg:  "Disclose findings from #7 review",
+			want: nil,
+		},
+		{
+			name: "no keyword, bare reference is ignored",
+			msg:  "See #7 for context",
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := scanIssueRefs(tc.msg, tc.tracker)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("scanIssueRefs(%q, %q) = %v, want %v", tc.msg, tc.tracker, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalIssueID(t *testing.T) {
+	cases := []struct {
+		tracker string
+		raw     string
+		want    string
+	}{
+		{tracker: "", raw: "12", want: "#12"},
+		{tracker: "", raw: "PROJ-45", want: "PROJ-45"},
+		{tracker: "github", raw: "12", want: "#12"},
+		{tracker: "github", raw: "#12", want: "#12"},
+		{tracker: "jira", raw: "PROJ-45", want: "PROJ-45"},
+		{tracker: "gitlab", raw: "!7", want: "!7"},
+	}
+
+	for _, tc := range cases {
+		got := canonicalIssueID(tc.tracker, tc.raw)
+		if got != tc.want {
+			t.Errorf("canonicalIssueID(%q, %q) = %q, want %q", tc.tracker, tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestDedupeIssueIDs(t *testing.T) {
+	got := dedupeIssueIDs([]string{"#12", "#13", "#12", "", "#13"})
+	want := []string{"#12", "#13"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupeIssueIDs() = %v, want %v", got, want)
+	}
+}
+
+// TestDedupeIssueIDsAfterCanonicalization is a regression test: two
+// differently-spelled raw references to the same issue (e.g. a
+// first-line id alongside one scanned from a "Fixes #12" trailer)
+// must collapse to a single trailer. Deduping has to run on the
+// canonical form, since deduping the raw strings first leaves both
+// spellings in place and each then canonicalizes to the same id.
+func TestDedupeIssueIDsAfterCanonicalization(t *testing.T) {
+	raw := []string{"12", "#12"}
+
+	canonical := make([]string, len(raw))
+	for i, id := range raw {
+		canonical[i] = canonicalIssueID("", id)
+	}
+	got := dedupeIssueIDs(canonical)
+
+	want := []string{"#12"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupeIssueIDs(canonicalized %v) = %v, want %v", raw, got, want)
+	}
+}
+
+func TestNameEmail(t *testing.T) {
+	cases := []struct {
+		in        string
+		wantName  string
+		wantEmail string
+	}{
+		{
+			in:        "Co-authored-by: Ada Lovelace <ada@example.com>",
+			wantName:  "Ada Lovelace",
+			wantEmail: "ada@example.com",
+		},
+		{
+			in:        "Ada Lovelace <ada@example.com>",
+			wantName:  "Ada Lovelace",
+			wantEmail: "ada@example.com",
+		},
+		{
+			in:        "Co-authored-by: O'Brien: Smith <bob@example.com>",
+			wantName:  "O'Brien: Smith",
+			wantEmail: "bob@example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		name, email := nameEmail(tc.in)
+		if name != tc.wantName || email != tc.wantEmail {
+			t.Errorf("nameEmail(%q) = (%q, %q), want (%q, %q)", tc.in, name, email, tc.wantName, tc.wantEmail)
+		}
+	}
+}